@@ -0,0 +1,69 @@
+// Copyright (c) 2015-2022 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import "sync"
+
+// bucketQuorumTracker deduplicates bucket name sightings coming from
+// multiple concurrent sources (local drives, or remote peer streams) and
+// reports, for each name, exactly once: the call that first brings its
+// sighting count to quorum. It backs the incremental quorum merge used by
+// ListBucketsStream and mergeListBucketsStreams so both share one definition
+// of "has this bucket reached quorum yet".
+//
+// quorum == 0 (a single drive or a single peer) means every sighting is
+// immediately at quorum, matching the `count < quorum` skip used by the
+// non-streaming ListBuckets fan-out.
+type bucketQuorumTracker struct {
+	quorum int
+
+	mu      sync.Mutex
+	counts  map[string]int
+	emitted map[string]bool
+}
+
+// newBucketQuorumTracker - tracker for a fan-out of the given quorum.
+func newBucketQuorumTracker(quorum int) *bucketQuorumTracker {
+	return &bucketQuorumTracker{
+		quorum:  quorum,
+		counts:  map[string]int{},
+		emitted: map[string]bool{},
+	}
+}
+
+// observe records one more sighting of name and reports whether this is the
+// sighting that first crosses quorum — the caller should emit exactly when
+// this returns true.
+func (t *bucketQuorumTracker) observe(name string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.counts[name]++
+	if t.counts[name] >= t.quorum && !t.emitted[name] {
+		t.emitted[name] = true
+		return true
+	}
+	return false
+}
+
+// hasEmitted reports whether name has already crossed quorum and been emitted.
+func (t *bucketQuorumTracker) hasEmitted(name string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.emitted[name]
+}
@@ -0,0 +1,161 @@
+// Copyright (c) 2015-2022 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBucketQuorumTrackerObserve(t *testing.T) {
+	testCases := []struct {
+		name    string
+		quorum  int
+		sights  int
+		wantAt  int // 1-indexed call number that should report ready, 0 if never
+	}{
+		{"quorum zero emits on first sighting", 0, 1, 1},
+		{"quorum zero still emits only once", 0, 3, 1},
+		{"quorum two waits for second sighting", 2, 3, 2},
+		{"quorum one emits on first sighting", 1, 2, 1},
+		{"below quorum never emits", 3, 2, 0},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tr := newBucketQuorumTracker(tc.quorum)
+			readyAt := 0
+			for i := 1; i <= tc.sights; i++ {
+				if tr.observe("bucket") {
+					if readyAt != 0 {
+						t.Fatalf("observe() reported ready twice, first at %d, again at %d", readyAt, i)
+					}
+					readyAt = i
+				}
+			}
+			if readyAt != tc.wantAt {
+				t.Fatalf("got ready at call %d, want %d", readyAt, tc.wantAt)
+			}
+			if tc.wantAt != 0 && !tr.hasEmitted("bucket") {
+				t.Fatalf("hasEmitted() = false after observe() returned true")
+			}
+			if tc.wantAt == 0 && tr.hasEmitted("bucket") {
+				t.Fatalf("hasEmitted() = true but quorum was never reached")
+			}
+		})
+	}
+}
+
+func TestBucketQuorumTrackerIndependentNames(t *testing.T) {
+	tr := newBucketQuorumTracker(2)
+
+	if tr.observe("a") {
+		t.Fatalf("bucket a should not be ready after a single sighting at quorum 2")
+	}
+	if tr.observe("b") {
+		t.Fatalf("bucket b should not be ready after its first sighting at quorum 2")
+	}
+	if !tr.observe("b") {
+		t.Fatalf("bucket b should be ready after its second sighting")
+	}
+
+	if tr.hasEmitted("a") {
+		t.Fatalf("bucket a should not have been emitted, only one sighting was recorded")
+	}
+	if !tr.hasEmitted("b") {
+		t.Fatalf("bucket b should have been emitted after two sightings")
+	}
+}
+
+func TestFilterByQuorum(t *testing.T) {
+	t0 := time.Unix(0, 0)
+	values := map[string]time.Time{"a": t0, "b": t0, "c": t0}
+
+	testCases := []struct {
+		name   string
+		counts map[string]int
+		quorum int
+		want   []string
+	}{
+		{
+			name:   "quorum two keeps only names seen twice or more",
+			counts: map[string]int{"a": 2, "b": 1, "c": 3},
+			quorum: 2,
+			want:   []string{"a", "c"},
+		},
+		{
+			name:   "quorum zero keeps every observed name, single drive/peer case",
+			counts: map[string]int{"a": 1},
+			quorum: 0,
+			want:   []string{"a"},
+		},
+		{
+			name:   "nothing meets quorum",
+			counts: map[string]int{"a": 1, "b": 1},
+			quorum: 2,
+			want:   nil,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := filterByQuorum(tc.counts, values, tc.quorum)
+			if len(got) != len(tc.want) {
+				t.Fatalf("filterByQuorum() = %v, want names %v", got, tc.want)
+			}
+			for _, name := range tc.want {
+				if _, ok := got[name]; !ok {
+					t.Fatalf("filterByQuorum() missing expected name %q, got %v", name, got)
+				}
+			}
+		})
+	}
+}
+
+// TestListBucketsStreamDeletedOnlyGating exercises the rule ListBucketsStream
+// applies to its deleted-only tail: of the names that reach deleted-tombstone
+// quorum (as filterByQuorum would produce from quorumDeletedVols), only the
+// ones never emitted live should be surfaced, and each exactly once.
+func TestListBucketsStreamDeletedOnlyGating(t *testing.T) {
+	const quorum = 1
+
+	liveTracker := newBucketQuorumTracker(quorum)
+	if !liveTracker.observe("live-bucket") {
+		t.Fatalf("expected live-bucket to reach quorum immediately at quorum=1")
+	}
+
+	deletedQuorum := filterByQuorum(map[string]int{
+		"live-bucket":         1, // stale tombstone left over from a prior delete+recreate
+		"deleted-only-bucket": 1,
+	}, map[string]time.Time{
+		"live-bucket":         time.Unix(1, 0),
+		"deleted-only-bucket": time.Unix(2, 0),
+	}, quorum)
+
+	var emitted []string
+	for name := range deletedQuorum {
+		if liveTracker.hasEmitted(name) {
+			continue
+		}
+		emitted = append(emitted, name)
+	}
+
+	if len(emitted) != 1 || emitted[0] != "deleted-only-bucket" {
+		t.Fatalf("deleted-only tail emitted %v, want exactly [deleted-only-bucket]", emitted)
+	}
+}
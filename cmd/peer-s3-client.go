@@ -0,0 +1,261 @@
+// Copyright (c) 2015-2022 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"sync"
+
+	"github.com/minio/madmin-go/v3"
+	"github.com/minio/minio/internal/grid"
+	"github.com/minio/minio/internal/logger"
+	"github.com/minio/pkg/v2/sync/errgroup"
+)
+
+// remotePeerS3Client - implements the peer-s3 fan-out calls for a single
+// remote peer over the grid. One instance is kept per peer in
+// globalNotificationSys and re-used across requests; the underlying grid
+// connection is pooled and reconnects transparently.
+type remotePeerS3Client struct {
+	host     string
+	gridConn func() *grid.Connection
+}
+
+func newRemotePeerS3Client(host string, gridConn func() *grid.Connection) *remotePeerS3Client {
+	return &remotePeerS3Client{host: host, gridConn: gridConn}
+}
+
+// String - returns the remote host this client talks to, used in logging and
+// quorum error reporting.
+func (client *remotePeerS3Client) String() string {
+	return client.host
+}
+
+// MakeBucket - creates bucket on this peer.
+func (client *remotePeerS3Client) MakeBucket(ctx context.Context, bucket string, opts MakeBucketOptions) error {
+	_, err := makeBucketRPC.Call(ctx, client.gridConn(), &MakeBucketRequest{
+		Bucket:      bucket,
+		ForceCreate: opts.ForceCreate,
+	})
+	return gridToStorageErr(err)
+}
+
+// DeleteBucket - deletes bucket on this peer.
+func (client *remotePeerS3Client) DeleteBucket(ctx context.Context, bucket string, opts DeleteBucketOptions) error {
+	_, err := deleteBucketRPC.Call(ctx, client.gridConn(), &DeleteBucketRequest{
+		Bucket:      bucket,
+		ForceDelete: opts.Force,
+	})
+	return gridToStorageErr(err)
+}
+
+// GetBucketInfo - fetches bucket info as seen by this peer.
+func (client *remotePeerS3Client) GetBucketInfo(ctx context.Context, bucket string, opts BucketOptions) (BucketInfo, error) {
+	resp, err := getBucketInfoRPC.Call(ctx, client.gridConn(), &GetBucketInfoRequest{
+		Bucket:  bucket,
+		Deleted: opts.Deleted,
+	})
+	if err != nil {
+		return BucketInfo{}, gridToStorageErr(err)
+	}
+	return resp.Info, nil
+}
+
+// ListBuckets - lists buckets known to this peer.
+func (client *remotePeerS3Client) ListBuckets(ctx context.Context, opts BucketOptions) ([]BucketInfo, error) {
+	resp, err := listBucketsRPC.Call(ctx, client.gridConn(), &ListBucketsRequest{
+		Deleted: opts.Deleted,
+	})
+	if err != nil {
+		return nil, gridToStorageErr(err)
+	}
+	return resp.Buckets, nil
+}
+
+// HealBucket - heals bucket on this peer.
+func (client *remotePeerS3Client) HealBucket(ctx context.Context, bucket string, opts madmin.HealOpts) (madmin.HealResultItem, error) {
+	resp, err := healBucketRPC.Call(ctx, client.gridConn(), &HealBucketRequest{
+		Bucket: bucket,
+		Opts:   opts,
+	})
+	if err != nil {
+		return madmin.HealResultItem{}, gridToStorageErr(err)
+	}
+	return resp.Result, nil
+}
+
+// ListBucketsStream - streams the buckets known to this peer onto ch as they
+// are discovered, instead of waiting for the full set to be gathered.
+func (client *remotePeerS3Client) ListBucketsStream(ctx context.Context, opts BucketOptions, ch chan<- BucketInfo) error {
+	st, err := listBucketsStreamRPC.Call(ctx, client.gridConn(), &ListBucketsRequest{
+		Deleted: opts.Deleted,
+	})
+	if err != nil {
+		return gridToStorageErr(err)
+	}
+
+	for bi := range st.Results() {
+		select {
+		case ch <- *bi:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	return gridToStorageErr(st.Err())
+}
+
+// mergeListBucketsStreams - k-way merges the per-peer bucket streams,
+// deduplicates by bucket name and applies the existing read-quorum rule
+// incrementally: a bucket is forwarded to out as soon as it has been seen by
+// quorum many peers, without waiting for every peer to finish. The S3 XML
+// response for the ListBuckets API can start being built as entries arrive,
+// instead of holding the full bucket list in memory. Returns a read-quorum
+// error, same as getBucketInfoLocal/listBucketsLocal, if too many peers
+// failed to stream their bucket set.
+func mergeListBucketsStreams(ctx context.Context, peers []*remotePeerS3Client, opts BucketOptions, out chan<- BucketInfo) error {
+	quorum := len(peers) / 2
+	tracker := newBucketQuorumTracker(quorum)
+
+	var mu sync.Mutex
+	infos := map[string]BucketInfo{}
+
+	g := errgroup.WithNErrs(len(peers))
+	for index, peer := range peers {
+		index := index
+		peer := peer
+		g.Go(func() error {
+			ch := make(chan BucketInfo)
+			errCh := make(chan error, 1)
+
+			go func() {
+				errCh <- peer.ListBucketsStream(ctx, opts, ch)
+				close(ch)
+			}()
+
+			for bi := range ch {
+				mu.Lock()
+				if _, ok := infos[bi.Name]; !ok {
+					infos[bi.Name] = bi
+				}
+				info := infos[bi.Name]
+				mu.Unlock()
+
+				if !tracker.observe(bi.Name) {
+					continue
+				}
+
+				select {
+				case out <- info:
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			}
+
+			return <-errCh
+		}, index)
+	}
+
+	errs := g.Wait()
+	for index, err := range errs {
+		if err != nil {
+			logger.LogOnceIf(ctx, err, peers[index].String())
+		}
+	}
+
+	return reduceReadQuorumErrs(ctx, errs, bucketOpIgnoredErrs, quorum)
+}
+
+// gridToStorageErr - translates an error coming back from a peer-s3 grid RPC
+// to the storage sentinel it was encoded from, so callers can keep using
+// errors.Is against the well known storage errors (errDiskNotFound,
+// errVolumeNotFound, ...) regardless of whether the peer is local or
+// remote. Named distinctly from the storage-rest-client toStorageErr, which
+// performs the same translation for the storage REST protocol.
+func gridToStorageErr(err error) error {
+	if err == nil {
+		return nil
+	}
+	switch err.Error() {
+	case errDiskNotFound.Error():
+		return errDiskNotFound
+	case errVolumeNotFound.Error():
+		return errVolumeNotFound
+	case errVolumeExists.Error():
+		return errVolumeExists
+	case errVolumeNotEmpty.Error():
+		return errVolumeNotEmpty
+	case errFileNotFound.Error():
+		return errFileNotFound
+	}
+	return err
+}
+
+// HealBuckets - heals the given bucket on every peer passed in, fanning the
+// heal call out over the pooled grid connections instead of one-shot HTTP dials.
+func HealBuckets(ctx context.Context, peers []*remotePeerS3Client, bucket string, opts madmin.HealOpts) ([]madmin.HealResultItem, error) {
+	results := make([]madmin.HealResultItem, len(peers))
+
+	g := errgroup.WithNErrs(len(peers))
+	for index, peer := range peers {
+		index := index
+		peer := peer
+		g.Go(func() error {
+			res, err := peer.HealBucket(ctx, bucket, opts)
+			if err != nil {
+				return err
+			}
+			results[index] = res
+			return nil
+		}, index)
+	}
+
+	errs := g.Wait()
+	for index, err := range errs {
+		if err != nil {
+			logger.LogOnceIf(ctx, err, peers[index].String())
+		}
+	}
+
+	return results, nil
+}
+
+// ListBuckets - lists and merges the bucket sets reported by every peer,
+// applying the existing read-quorum rule per bucket name. Callers that can
+// consume results incrementally (e.g. the S3 ListBuckets API handler
+// building its XML response) should call mergeListBucketsStreams directly
+// instead of waiting for this to return the full slice.
+func ListBuckets(ctx context.Context, peers []*remotePeerS3Client, opts BucketOptions) ([]BucketInfo, error) {
+	ch := make(chan BucketInfo)
+	errCh := make(chan error, 1)
+
+	go func() {
+		errCh <- mergeListBucketsStreams(ctx, peers, opts, ch)
+		close(ch)
+	}()
+
+	merged := make([]BucketInfo, 0, 32)
+	for bi := range ch {
+		merged = append(merged, bi)
+	}
+
+	if err := <-errCh; err != nil {
+		return nil, err
+	}
+	return merged, nil
+}
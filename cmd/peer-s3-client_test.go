@@ -0,0 +1,55 @@
+// Copyright (c) 2015-2022 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestGridToStorageErr(t *testing.T) {
+	if err := gridToStorageErr(nil); err != nil {
+		t.Fatalf("gridToStorageErr(nil) = %v, want nil", err)
+	}
+
+	testCases := []struct {
+		name     string
+		encoded  error
+		sentinel error
+	}{
+		{"disk not found", errors.New(errDiskNotFound.Error()), errDiskNotFound},
+		{"volume not found", errors.New(errVolumeNotFound.Error()), errVolumeNotFound},
+		{"volume exists", errors.New(errVolumeExists.Error()), errVolumeExists},
+		{"volume not empty", errors.New(errVolumeNotEmpty.Error()), errVolumeNotEmpty},
+		{"file not found", errors.New(errFileNotFound.Error()), errFileNotFound},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := gridToStorageErr(tc.encoded)
+			if !errors.Is(got, tc.sentinel) {
+				t.Fatalf("gridToStorageErr(%v) = %v, want errors.Is(_, %v) to hold", tc.encoded, got, tc.sentinel)
+			}
+		})
+	}
+
+	unmapped := errors.New("some transient network error")
+	if got := gridToStorageErr(unmapped); got != unmapped {
+		t.Fatalf("gridToStorageErr(%v) = %v, want the original error returned unchanged for an unmapped message", unmapped, got)
+	}
+}
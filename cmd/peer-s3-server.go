@@ -19,11 +19,14 @@ package cmd
 
 import (
 	"context"
-	"encoding/gob"
 	"errors"
 	"net/http"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/minio/madmin-go/v3"
+	"github.com/minio/minio/internal/grid"
 	"github.com/minio/minio/internal/logger"
 	"github.com/minio/mux"
 	"github.com/minio/pkg/v2/sync/errgroup"
@@ -38,19 +41,84 @@ const (
 )
 
 const (
-	peerS3MethodHealth        = "/health"
-	peerS3MethodMakeBucket    = "/make-bucket"
-	peerS3MethodGetBucketInfo = "/get-bucket-info"
-	peerS3MethodDeleteBucket  = "/delete-bucket"
-	peerS3MethodListBuckets   = "/list-buckets"
-	peerS3MethodHealBucket    = "/heal-bucket"
+	peerS3MethodHealth = "/health"
 )
 
-const (
-	peerS3Bucket            = "bucket"
-	peerS3BucketDeleted     = "bucket-deleted"
-	peerS3BucketForceCreate = "force-create"
-	peerS3BucketForceDelete = "force-delete"
+//go:generate msgp -file=$GOFILE -unexported
+
+// MakeBucketRequest - request to make a bucket on a peer.
+type MakeBucketRequest struct {
+	Bucket      string `msg:"b"`
+	ForceCreate bool   `msg:"fc"`
+}
+
+// DeleteBucketRequest - request to delete a bucket on a peer.
+type DeleteBucketRequest struct {
+	Bucket      string `msg:"b"`
+	ForceDelete bool   `msg:"fd"`
+}
+
+// GetBucketInfoRequest - request to fetch bucket info from a peer.
+type GetBucketInfoRequest struct {
+	Bucket  string `msg:"b"`
+	Deleted bool   `msg:"d"`
+}
+
+// GetBucketInfoResponse - response carrying the BucketInfo found on a peer.
+type GetBucketInfoResponse struct {
+	Info BucketInfo `msg:"i"`
+}
+
+// ListBucketsRequest - request to list buckets present on a peer.
+type ListBucketsRequest struct {
+	Deleted bool `msg:"d"`
+}
+
+// ListBucketsResponse - response carrying all buckets found on a peer.
+type ListBucketsResponse struct {
+	Buckets []BucketInfo `msg:"b"`
+}
+
+// HealBucketRequest - request to heal a single bucket on a peer.
+type HealBucketRequest struct {
+	Bucket string          `msg:"b"`
+	Opts   madmin.HealOpts `msg:"o"`
+}
+
+// HealBucketResponse - response carrying the heal result for a bucket.
+type HealBucketResponse struct {
+	Result madmin.HealResultItem `msg:"r"`
+}
+
+var (
+	// Static pool of grid handlers for each peerS3 method, remotePeerS3Client
+	// uses these to issue calls, registerPeerS3Handlers registers handlers for
+	// these against the grid manager.
+	makeBucketRPC = grid.NewSingleHandler[*MakeBucketRequest, grid.NoPayload](
+		grid.HandlerMakeBucket, func() *MakeBucketRequest { return &MakeBucketRequest{} },
+		func() grid.NoPayload { return grid.NoPayload{} })
+
+	deleteBucketRPC = grid.NewSingleHandler[*DeleteBucketRequest, grid.NoPayload](
+		grid.HandlerDeleteBucket, func() *DeleteBucketRequest { return &DeleteBucketRequest{} },
+		func() grid.NoPayload { return grid.NoPayload{} })
+
+	getBucketInfoRPC = grid.NewSingleHandler[*GetBucketInfoRequest, *GetBucketInfoResponse](
+		grid.HandlerGetBucketInfo, func() *GetBucketInfoRequest { return &GetBucketInfoRequest{} },
+		func() *GetBucketInfoResponse { return &GetBucketInfoResponse{} })
+
+	listBucketsRPC = grid.NewSingleHandler[*ListBucketsRequest, *ListBucketsResponse](
+		grid.HandlerListBuckets, func() *ListBucketsRequest { return &ListBucketsRequest{} },
+		func() *ListBucketsResponse { return &ListBucketsResponse{} })
+
+	healBucketRPC = grid.NewSingleHandler[*HealBucketRequest, *HealBucketResponse](
+		grid.HandlerHealBucket, func() *HealBucketRequest { return &HealBucketRequest{} },
+		func() *HealBucketResponse { return &HealBucketResponse{} })
+
+	// listBucketsStreamRPC streams one BucketInfo per message instead of
+	// buffering the whole bucket set like listBucketsRPC.
+	listBucketsStreamRPC = grid.NewStream[*ListBucketsRequest, grid.NoPayload, *BucketInfo](
+		grid.HandlerListBucketsStream, func() *ListBucketsRequest { return &ListBucketsRequest{} },
+		nil, func() *BucketInfo { return &BucketInfo{} })
 )
 
 type peerS3Server struct{}
@@ -252,6 +320,194 @@ func listBucketsLocal(ctx context.Context, opts BucketOptions) (buckets []Bucket
 	return buckets, nil
 }
 
+// listBucketsStreamConcurrency - bounds the number of local drives walked
+// concurrently by ListBucketsStream.
+const listBucketsStreamConcurrency = 32
+
+// ListBucketsStream - walks each local drive's volume directory and streams
+// a BucketInfo to ch as soon as the bucket is seen by read-quorum many
+// drives, instead of buffering the full set in memory like listBucketsLocal
+// does. Drives are walked concurrently, so entries arrive on ch in whatever
+// order they cross quorum, not in bucket-name order. Entries are emitted at
+// most once; ch is never closed by this function, the caller owns its
+// lifecycle.
+//
+// When opts.Deleted is set, the deleted-buckets prefix of every drive is
+// read up front so that both the Deleted timestamp set on a live bucket and
+// the deleted-only tail emitted afterwards are gated on the same read-quorum
+// rule that listDeletedBuckets applies for the non-streaming path, rather
+// than on whichever single drive happens to answer first.
+func ListBucketsStream(ctx context.Context, opts BucketOptions, ch chan<- BucketInfo) error {
+	globalLocalDrivesMu.RLock()
+	localDrives := cloneDrives(globalLocalDrives)
+	globalLocalDrivesMu.RUnlock()
+
+	quorum := len(localDrives) / 2
+
+	var deletedQuorum map[string]time.Time
+	if opts.Deleted {
+		var err error
+		deletedQuorum, err = quorumDeletedVols(ctx, localDrives, quorum)
+		if err != nil {
+			return err
+		}
+	}
+
+	liveTracker := newBucketQuorumTracker(quorum)
+	var mu sync.Mutex
+	infos := map[string]BucketInfo{}
+
+	g := errgroup.WithNErrs(len(localDrives)).WithConcurrency(listBucketsStreamConcurrency)
+	for index := range localDrives {
+		index := index
+		g.Go(func() error {
+			drive := localDrives[index]
+			if drive == nil {
+				return errDiskNotFound
+			}
+
+			vols, err := drive.ListVols(ctx)
+			if err != nil {
+				return err
+			}
+
+			for _, v := range vols {
+				if isMinioMetaBucketName(v.Name) {
+					continue
+				}
+
+				mu.Lock()
+				if _, ok := infos[v.Name]; !ok {
+					infos[v.Name] = BucketInfo{Name: v.Name, Created: v.Created}
+				}
+				bi := infos[v.Name]
+				mu.Unlock()
+
+				if !liveTracker.observe(v.Name) {
+					continue
+				}
+
+				if when, ok := deletedQuorum[v.Name]; ok {
+					bi.Deleted = when
+				}
+
+				select {
+				case ch <- bi:
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			}
+			return nil
+		}, index)
+	}
+
+	errs := g.Wait()
+	if err := reduceReadQuorumErrs(ctx, errs, bucketOpIgnoredErrs, quorum); err != nil {
+		return err
+	}
+
+	for name, when := range deletedQuorum {
+		if liveTracker.hasEmitted(name) {
+			continue
+		}
+
+		select {
+		case ch <- BucketInfo{Name: name, Deleted: when}:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	return nil
+}
+
+// deletedVol - a single drive's tombstone entry for a deleted bucket.
+type deletedVol struct {
+	name    string
+	created time.Time
+}
+
+// listDeletedVols - lists the deleted-bucket tombstones recorded on a single
+// drive under minioMetaBucket/bucketMetaPrefix/deletedBucketsPrefix.
+func listDeletedVols(ctx context.Context, drive StorageAPI) ([]deletedVol, error) {
+	entries, err := drive.ListDir(ctx, minioMetaBucket, pathJoin(bucketMetaPrefix, deletedBucketsPrefix), -1)
+	if err != nil {
+		if err == errFileNotFound || err == errVolumeNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	deleted := make([]deletedVol, 0, len(entries))
+	for _, name := range entries {
+		name = strings.TrimSuffix(name, SlashSeparator)
+		vi, serr := drive.StatVol(ctx, pathJoin(minioMetaBucket, bucketMetaPrefix, deletedBucketsPrefix, name))
+		if serr != nil {
+			continue
+		}
+		deleted = append(deleted, deletedVol{name: name, created: vi.Created})
+	}
+	return deleted, nil
+}
+
+// quorumDeletedVols - reads the deleted-bucket tombstones across all local
+// drives and returns only the names that reached read quorum, each paired
+// with its earliest recorded tombstone time. This mirrors the quorum rule
+// listDeletedBuckets applies for the non-streaming listBucketsLocal path.
+func quorumDeletedVols(ctx context.Context, localDrives []StorageAPI, quorum int) (map[string]time.Time, error) {
+	var mu sync.Mutex
+	counts := map[string]int{}
+	created := map[string]time.Time{}
+
+	g := errgroup.WithNErrs(len(localDrives)).WithConcurrency(listBucketsStreamConcurrency)
+	for index := range localDrives {
+		index := index
+		g.Go(func() error {
+			drive := localDrives[index]
+			if drive == nil {
+				return errDiskNotFound
+			}
+
+			deleted, err := listDeletedVols(ctx, drive)
+			if err != nil {
+				return err
+			}
+
+			mu.Lock()
+			for _, d := range deleted {
+				counts[d.name]++
+				if t, ok := created[d.name]; !ok || d.created.Before(t) {
+					created[d.name] = d.created
+				}
+			}
+			mu.Unlock()
+			return nil
+		}, index)
+	}
+
+	errs := g.Wait()
+	if err := reduceReadQuorumErrs(ctx, errs, bucketOpIgnoredErrs, quorum); err != nil {
+		return nil, err
+	}
+
+	return filterByQuorum(counts, created, quorum), nil
+}
+
+// filterByQuorum - keeps only the names whose count has reached quorum,
+// pairing each surviving name with its recorded value. Factored out of
+// quorumDeletedVols so the quorum-cutoff itself can be tested without a
+// StorageAPI.
+func filterByQuorum(counts map[string]int, values map[string]time.Time, quorum int) map[string]time.Time {
+	out := make(map[string]time.Time, len(counts))
+	for name, count := range counts {
+		if count < quorum {
+			continue
+		}
+		out[name] = values[name]
+	}
+	return out
+}
+
 func cloneDrives(drives []StorageAPI) []StorageAPI {
 	newDrives := make([]StorageAPI, len(drives))
 	copy(newDrives, drives)
@@ -373,26 +629,93 @@ func makeBucketLocal(ctx context.Context, bucket string, opts MakeBucketOptions)
 	return reduceWriteQuorumErrs(ctx, errs, bucketOpIgnoredErrs, (len(localDrives)/2)+1)
 }
 
-func (s *peerS3Server) ListBucketsHandler(w http.ResponseWriter, r *http.Request) {
-	if !s.IsValid(w, r) {
-		return
+// MakeBucketHandler - grid handler for makeBucketRPC, creates a bucket on all
+// local drives of this peer.
+func MakeBucketHandler(ctx context.Context, req *MakeBucketRequest) (grid.NoPayload, *grid.RemoteErr) {
+	err := makeBucketLocal(ctx, req.Bucket, MakeBucketOptions{
+		ForceCreate: req.ForceCreate,
+	})
+	if err != nil {
+		return grid.NoPayload{}, grid.NewRemoteErr(err)
 	}
+	return grid.NoPayload{}, nil
+}
 
-	bucketDeleted := r.Form.Get(peerS3BucketDeleted) == "true"
+// DeleteBucketHandler - grid handler for deleteBucketRPC, deletes a bucket on
+// all local drives of this peer.
+func DeleteBucketHandler(ctx context.Context, req *DeleteBucketRequest) (grid.NoPayload, *grid.RemoteErr) {
+	err := deleteBucketLocal(ctx, req.Bucket, DeleteBucketOptions{
+		Force: req.ForceDelete,
+	})
+	if err != nil {
+		return grid.NoPayload{}, grid.NewRemoteErr(err)
+	}
+	return grid.NoPayload{}, nil
+}
 
-	buckets, err := listBucketsLocal(r.Context(), BucketOptions{
-		Deleted: bucketDeleted,
+// GetBucketInfoHandler - grid handler for getBucketInfoRPC, returns bucket
+// info as seen by the local drives of this peer.
+func GetBucketInfoHandler(ctx context.Context, req *GetBucketInfoRequest) (*GetBucketInfoResponse, *grid.RemoteErr) {
+	info, err := getBucketInfoLocal(ctx, req.Bucket, BucketOptions{
+		Deleted: req.Deleted,
 	})
 	if err != nil {
-		s.writeErrorResponse(w, err)
-		return
+		return nil, grid.NewRemoteErr(err)
 	}
+	return &GetBucketInfoResponse{Info: info}, nil
+}
+
+// ListBucketsHandler - grid handler for listBucketsRPC, returns all buckets
+// known to the local drives of this peer.
+func ListBucketsHandler(ctx context.Context, req *ListBucketsRequest) (*ListBucketsResponse, *grid.RemoteErr) {
+	buckets, err := listBucketsLocal(ctx, BucketOptions{
+		Deleted: req.Deleted,
+	})
+	if err != nil {
+		return nil, grid.NewRemoteErr(err)
+	}
+	return &ListBucketsResponse{Buckets: buckets}, nil
+}
 
-	logger.LogIf(r.Context(), gob.NewEncoder(w).Encode(buckets))
+// HealBucketHandler - grid handler for healBucketRPC, heals a bucket across
+// the local drives of this peer.
+func HealBucketHandler(ctx context.Context, req *HealBucketRequest) (*HealBucketResponse, *grid.RemoteErr) {
+	res, err := healBucketLocal(ctx, req.Bucket, req.Opts)
+	if err != nil {
+		return nil, grid.NewRemoteErr(err)
+	}
+	return &HealBucketResponse{Result: res}, nil
 }
 
-// registerPeerS3Handlers - register peer s3 router.
-func registerPeerS3Handlers(router *mux.Router) {
+// ListBucketsStreamHandler - grid stream handler for listBucketsStreamRPC,
+// streams BucketInfo entries for buckets known to the local drives of this
+// peer as they are discovered, rather than returning them all at once.
+func ListBucketsStreamHandler(ctx context.Context, req *ListBucketsRequest, _ <-chan grid.NoPayload, out chan<- *BucketInfo) *grid.RemoteErr {
+	ch := make(chan BucketInfo)
+	errCh := make(chan error, 1)
+
+	go func() {
+		errCh <- ListBucketsStream(ctx, BucketOptions{Deleted: req.Deleted}, ch)
+		close(ch)
+	}()
+
+	for bi := range ch {
+		bi := bi
+		select {
+		case out <- &bi:
+		case <-ctx.Done():
+			return grid.NewRemoteErr(ctx.Err())
+		}
+	}
+
+	if err := <-errCh; err != nil {
+		return grid.NewRemoteErr(err)
+	}
+	return nil
+}
+
+// registerPeerS3Handlers - register peer s3 router and grid handlers.
+func registerPeerS3Handlers(router *mux.Router, gm *grid.Manager) {
 	server := &peerS3Server{}
 	subrouter := router.PathPrefix(peerS3Prefix).Subrouter()
 
@@ -401,5 +724,11 @@ func registerPeerS3Handlers(router *mux.Router) {
 	}
 
 	subrouter.Methods(http.MethodPost).Path(peerS3VersionPrefix + peerS3MethodHealth).HandlerFunc(h(server.HealthHandler))
-	subrouter.Methods(http.MethodPost).Path(peerS3VersionPrefix + peerS3MethodListBuckets).HandlerFunc(h(server.ListBucketsHandler))
+
+	logger.FatalIf(makeBucketRPC.Register(gm, MakeBucketHandler), "unable to register makeBucketRPC handler")
+	logger.FatalIf(deleteBucketRPC.Register(gm, DeleteBucketHandler), "unable to register deleteBucketRPC handler")
+	logger.FatalIf(getBucketInfoRPC.Register(gm, GetBucketInfoHandler), "unable to register getBucketInfoRPC handler")
+	logger.FatalIf(listBucketsRPC.Register(gm, ListBucketsHandler), "unable to register listBucketsRPC handler")
+	logger.FatalIf(healBucketRPC.Register(gm, HealBucketHandler), "unable to register healBucketRPC handler")
+	logger.FatalIf(listBucketsStreamRPC.Register(gm, ListBucketsStreamHandler), "unable to register listBucketsStreamRPC handler")
 }